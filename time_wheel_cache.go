@@ -0,0 +1,119 @@
+package timewheel
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// cacheSlotNum and cacheTick size the TimeWheel backing a Cache: a one
+// minute span at 100ms resolution, which is plenty for connection-pool
+// and session style TTLs while keeping tests fast.
+const (
+	cacheSlotNum = 600
+	cacheTick    = 100 * time.Millisecond
+)
+
+// Cache is an expiring key/value store built on top of a TimeWheel,
+// analogous to go-zero's Cache+TimingWheel pairing: entries expire on
+// their own TTL and, via MoveTimer, can have that TTL refreshed in O(1)
+// without removing and re-adding the entry (the connection-pool
+// "heartbeat" use case).
+type Cache struct {
+	mu         sync.Mutex
+	tw         *TimeWheel
+	defaultTTL time.Duration
+	onEvict    func(key string, value any)
+	data       map[string]any
+}
+
+// NewCache creates a Cache whose entries expire after defaultTTL unless
+// SetWithExpire specifies otherwise. onEvict, if non-nil, is called with
+// the key and value of every entry that expires (not ones removed by Del).
+func NewCache(defaultTTL time.Duration, onEvict func(key string, value any)) *Cache {
+	return &Cache{
+		tw:         NewTimeWheel(cacheSlotNum, cacheTick),
+		defaultTTL: defaultTTL,
+		onEvict:    onEvict,
+		data:       make(map[string]any),
+	}
+}
+
+// Stop releases the Cache's underlying TimeWheel goroutine.
+func (c *Cache) Stop() {
+	c.tw.Stop()
+}
+
+// Set stores value under key with the Cache's default TTL.
+func (c *Cache) Set(key string, value any) {
+	c.SetWithExpire(key, value, c.defaultTTL)
+}
+
+// SetWithExpire stores value under key, expiring it after ttl (with a
+// small jitter so a batch of equal-TTL entries doesn't all expire, and
+// tick, on the same slot).
+func (c *Cache) SetWithExpire(key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	c.data[key] = value
+	c.mu.Unlock()
+
+	c.tw.AddTask(key, func() { c.expire(key) }, time.Now().Add(jitter(ttl)))
+}
+
+// Get returns the value stored under key, if any and not yet expired.
+func (c *Cache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.data[key]
+	return value, ok
+}
+
+// Del removes key immediately, without invoking onEvict.
+func (c *Cache) Del(key string) {
+	c.mu.Lock()
+	_, ok := c.data[key]
+	delete(c.data, key)
+	c.mu.Unlock()
+
+	if ok {
+		c.tw.RemoveTask(key)
+	}
+}
+
+// MoveTimer refreshes key's TTL to newTTL in O(1), without touching the
+// stored value or re-registering the expiry task from scratch. It
+// reports whether key was present.
+func (c *Cache) MoveTimer(key string, newTTL time.Duration) bool {
+	c.mu.Lock()
+	_, ok := c.data[key]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	return c.tw.MoveTask(key, jitter(newTTL))
+}
+
+func (c *Cache) expire(key string) {
+	c.mu.Lock()
+	value, ok := c.data[key]
+	if ok {
+		delete(c.data, key)
+	}
+	c.mu.Unlock()
+
+	if ok && c.onEvict != nil {
+		c.onEvict(key, value)
+	}
+}
+
+// jitter nudges d by up to ±5%, so a large batch of entries scheduled
+// with the same TTL don't all land in the same slot and tick together.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	spread := float64(d) * 0.05
+	offset := (rand.Float64()*2 - 1) * spread
+	return d + time.Duration(offset)
+}
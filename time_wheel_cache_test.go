@@ -0,0 +1,107 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheSetGet(t *testing.T) {
+	c := NewCache(time.Second, nil)
+	defer c.Stop()
+
+	c.Set("key1", "value1")
+	value, ok := c.Get("key1")
+	if !ok || value != "value1" {
+		t.Errorf("expected (value1, true), got (%v, %v)", value, ok)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	var mu sync.Mutex
+	evicted := make(map[string]any)
+	c := NewCache(time.Second, func(key string, value any) {
+		mu.Lock()
+		evicted[key] = value
+		mu.Unlock()
+	})
+	defer c.Stop()
+
+	c.SetWithExpire("key1", "value1", 150*time.Millisecond)
+
+	if _, ok := c.Get("key1"); !ok {
+		t.Fatal("expected key1 to be present immediately after Set")
+	}
+
+	time.Sleep(400 * time.Millisecond)
+
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected key1 to have expired")
+	}
+
+	mu.Lock()
+	value, ok := evicted["key1"]
+	mu.Unlock()
+	if !ok || value != "value1" {
+		t.Errorf("expected onEvict to have been called with (key1, value1), got (%v, %v)", value, ok)
+	}
+}
+
+func TestCacheDel(t *testing.T) {
+	evicted := false
+	c := NewCache(time.Second, func(key string, value any) {
+		evicted = true
+	})
+	defer c.Stop()
+
+	c.SetWithExpire("key1", "value1", 150*time.Millisecond)
+	c.Del("key1")
+
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected key1 to be gone immediately after Del")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if evicted {
+		t.Error("onEvict should not fire for an entry removed via Del")
+	}
+}
+
+func TestCacheMoveTimerRefreshesTTL(t *testing.T) {
+	evicted := false
+	c := NewCache(time.Second, func(key string, value any) {
+		evicted = true
+	})
+	defer c.Stop()
+
+	c.SetWithExpire("key1", "value1", 150*time.Millisecond)
+
+	// heartbeat refresh before the original TTL would have fired
+	time.Sleep(80 * time.Millisecond)
+	if !c.MoveTimer("key1", 300*time.Millisecond) {
+		t.Fatal("expected MoveTimer to report the key was present")
+	}
+
+	// past the original deadline, but not the refreshed one
+	time.Sleep(150 * time.Millisecond)
+	if _, ok := c.Get("key1"); !ok {
+		t.Error("expected key1 to still be present after MoveTimer extended its TTL")
+	}
+	if evicted {
+		t.Error("should not have evicted before the refreshed TTL elapsed")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if _, ok := c.Get("key1"); ok {
+		t.Error("expected key1 to expire after the refreshed TTL elapsed")
+	}
+}
+
+func TestCacheMoveTimerUnknownKey(t *testing.T) {
+	c := NewCache(time.Second, nil)
+	defer c.Stop()
+
+	if c.MoveTimer("missing", time.Second) {
+		t.Error("expected MoveTimer to report false for an unknown key")
+	}
+}
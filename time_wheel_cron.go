@@ -0,0 +1,151 @@
+package timewheel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronScheduler is a Scheduler driven by a standard 5-field cron
+// expression: minute hour day-of-month month day-of-week. It has minute
+// resolution, matching what the format can express.
+//
+// Day-of-month and day-of-week follow standard (Vixie) cron semantics: if
+// both fields are restricted (neither is "*"), a date matches when either
+// one does; if only one is restricted, the other (being "*", i.e. always
+// true) has no effect and the restricted field alone decides the match.
+type cronScheduler struct {
+	minute, hour, dom, month, dow cronField
+	domRestricted, dowRestricted  bool
+}
+
+// cronField is the set of values a single cron field matches.
+type cronField map[int]bool
+
+// NewCronScheduler parses a standard 5-field cron expression ("minute
+// hour dom month dow") into a Scheduler usable with ScheduleFunc. Each
+// field accepts "*", a single value, a comma-separated list, a range
+// ("a-b") and a step ("*/n" or "a-b/n"). Day-of-week accepts both 0 and
+// the standard Vixie-cron alias 7 for Sunday; 7 is folded into 0.
+func NewCronScheduler(expr string) (Scheduler, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("timewheel: cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	minute, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, err
+	}
+	hour, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, err
+	}
+	dom, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, err
+	}
+	month, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, err
+	}
+	dow, err := parseCronField(fields[4], 0, 7)
+	if err != nil {
+		return nil, err
+	}
+	if dow[7] {
+		dow[0] = true
+		delete(dow, 7)
+	}
+
+	return cronScheduler{
+		minute: minute, hour: hour, dom: dom, month: month, dow: dow,
+		domRestricted: fields[2] != "*", dowRestricted: fields[4] != "*",
+	}, nil
+}
+
+// maxCronLookahead bounds how far Next will search for a matching minute,
+// so a contradictory expression (e.g. Feb 31st) returns the zero Time
+// instead of looping forever.
+const maxCronLookahead = 4 * 366 * 24 * time.Hour
+
+func (s cronScheduler) Next(prev time.Time) time.Time {
+	t := prev.Add(time.Minute).Truncate(time.Minute)
+	deadline := prev.Add(maxCronLookahead)
+
+	for t.Before(deadline) {
+		dayMatches := s.dom[t.Day()] && s.dow[int(t.Weekday())]
+		if s.domRestricted && s.dowRestricted {
+			dayMatches = s.dom[t.Day()] || s.dow[int(t.Weekday())]
+		}
+		if s.month[int(t.Month())] && dayMatches && s.hour[t.Hour()] && s.minute[t.Minute()] {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+func parseCronField(field string, min, max int) (cronField, error) {
+	values := make(cronField)
+
+	for _, part := range strings.Split(field, ",") {
+		rangeExpr, step, err := splitCronStep(part)
+		if err != nil {
+			return nil, err
+		}
+
+		lo, hi := min, max
+		if rangeExpr != "*" {
+			lo, hi, err = parseCronRange(rangeExpr, min, max)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("timewheel: cron field %q out of range [%d, %d]", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+func splitCronStep(part string) (rangeExpr string, step int, err error) {
+	if idx := strings.IndexByte(part, '/'); idx >= 0 {
+		step, err = strconv.Atoi(part[idx+1:])
+		if err != nil || step <= 0 {
+			return "", 0, fmt.Errorf("timewheel: invalid cron step %q", part)
+		}
+		return part[:idx], step, nil
+	}
+	return part, 1, nil
+}
+
+func parseCronRange(expr string, min, max int) (lo, hi int, err error) {
+	if expr == "*" {
+		return min, max, nil
+	}
+
+	if idx := strings.IndexByte(expr, '-'); idx >= 0 {
+		lo, err = strconv.Atoi(expr[:idx])
+		if err != nil {
+			return 0, 0, fmt.Errorf("timewheel: invalid cron range %q", expr)
+		}
+		hi, err = strconv.Atoi(expr[idx+1:])
+		if err != nil {
+			return 0, 0, fmt.Errorf("timewheel: invalid cron range %q", expr)
+		}
+		return lo, hi, nil
+	}
+
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("timewheel: invalid cron value %q", expr)
+	}
+	return v, v, nil
+}
@@ -0,0 +1,107 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewCronSchedulerRejectsBadExpression(t *testing.T) {
+	if _, err := NewCronScheduler("* * *"); err == nil {
+		t.Error("expected an error for a cron expression with too few fields")
+	}
+	if _, err := NewCronScheduler("99 * * * *"); err == nil {
+		t.Error("expected an error for a minute value out of range")
+	}
+}
+
+func TestCronSchedulerNextEveryMinute(t *testing.T) {
+	s, err := NewCronScheduler("* * * * *")
+	if err != nil {
+		t.Fatalf("NewCronScheduler returned an error: %v", err)
+	}
+
+	prev := time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC)
+	next := s.Next(prev)
+	want := time.Date(2024, 1, 1, 10, 31, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
+
+func TestCronSchedulerNextSpecificMinute(t *testing.T) {
+	// every hour, at minute 5
+	s, err := NewCronScheduler("5 * * * *")
+	if err != nil {
+		t.Fatalf("NewCronScheduler returned an error: %v", err)
+	}
+
+	prev := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	next := s.Next(prev)
+	want := time.Date(2024, 1, 1, 11, 5, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
+
+func TestCronSchedulerDomDowOredWhenBothRestricted(t *testing.T) {
+	// "the 13th, and also any Friday" - when both day-of-month and
+	// day-of-week are restricted, standard cron fires on either match,
+	// so this should land on the first Friday-the-13th-or-earlier Friday
+	// or 13th, whichever comes first.
+	s, err := NewCronScheduler("0 12 13 * 5")
+	if err != nil {
+		t.Fatalf("NewCronScheduler returned an error: %v", err)
+	}
+
+	prev := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := s.Next(prev)
+	want := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC) // first Friday in January 2024
+	if !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
+
+func TestCronSchedulerDomOnlyRestrictedIgnoresDow(t *testing.T) {
+	// dow is "*" (unrestricted), so only dom should matter.
+	s, err := NewCronScheduler("0 12 13 * *")
+	if err != nil {
+		t.Fatalf("NewCronScheduler returned an error: %v", err)
+	}
+
+	prev := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := s.Next(prev)
+	want := time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
+
+func TestCronSchedulerDowAcceptsSevenAsSunday(t *testing.T) {
+	// 7 is the standard Vixie-cron alias for Sunday (0).
+	s, err := NewCronScheduler("0 12 * * 7")
+	if err != nil {
+		t.Fatalf("NewCronScheduler returned an error: %v", err)
+	}
+
+	prev := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	next := s.Next(prev)
+	want := time.Date(2024, 1, 7, 12, 0, 0, 0, time.UTC) // first Sunday
+	if !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
+
+func TestCronSchedulerNextWithStep(t *testing.T) {
+	// every 15 minutes
+	s, err := NewCronScheduler("*/15 * * * *")
+	if err != nil {
+		t.Fatalf("NewCronScheduler returned an error: %v", err)
+	}
+
+	prev := time.Date(2024, 1, 1, 10, 16, 0, 0, time.UTC)
+	next := s.Next(prev)
+	want := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next)
+	}
+}
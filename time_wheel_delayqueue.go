@@ -0,0 +1,122 @@
+package timewheel
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// delayQueueItem is one entry in a DelayQueue's heap, ordered by
+// expiration (unix milliseconds).
+type delayQueueItem struct {
+	value      any
+	expiration int64
+	index      int
+}
+
+// delayQueueHeap is a container/heap.Interface over delayQueueItems,
+// ordered earliest-expiration-first.
+type delayQueueHeap []*delayQueueItem
+
+func (h delayQueueHeap) Len() int           { return len(h) }
+func (h delayQueueHeap) Less(i, j int) bool { return h[i].expiration < h[j].expiration }
+func (h delayQueueHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayQueueHeap) Push(x any) {
+	item := x.(*delayQueueItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *delayQueueHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// DelayQueue is a thread-safe priority queue of items ordered by an
+// expiration time in unix milliseconds. It lets a poller block until the
+// earliest item is due rather than waking up on a fixed tick, which is
+// what keeps an idle TimeWheel from spinning its ticker for no reason.
+type DelayQueue struct {
+	mu      sync.Mutex
+	heap    delayQueueHeap
+	wakeupC chan struct{}
+}
+
+// NewDelayQueue creates an empty DelayQueue.
+func NewDelayQueue() *DelayQueue {
+	return &DelayQueue{wakeupC: make(chan struct{}, 1)}
+}
+
+// Offer adds value to the queue with the given expiration (unix
+// milliseconds). If value becomes the new earliest item, any in-progress
+// Poll sleeping on a later expiration is woken immediately so it can
+// re-sleep for the shorter duration.
+func (q *DelayQueue) Offer(value any, expirationMs int64) {
+	q.mu.Lock()
+	item := &delayQueueItem{value: value, expiration: expirationMs}
+	heap.Push(&q.heap, item)
+	becameHead := q.heap[0] == item
+	q.mu.Unlock()
+
+	if becameHead {
+		q.wakeup()
+	}
+}
+
+func (q *DelayQueue) wakeup() {
+	select {
+	case q.wakeupC <- struct{}{}:
+	default:
+	}
+}
+
+// Poll blocks until the earliest item's expiration has passed, then
+// removes and returns it. It returns nil if ctx is canceled first. nowFn
+// reports the current time in unix milliseconds, matching Offer's units.
+func (q *DelayQueue) Poll(ctx context.Context, nowFn func() int64) any {
+	for {
+		q.mu.Lock()
+		var sleepFor time.Duration
+		hasItem := len(q.heap) > 0
+		if hasItem {
+			item := q.heap[0]
+			if remaining := item.expiration - nowFn(); remaining <= 0 {
+				heap.Pop(&q.heap)
+				q.mu.Unlock()
+				return item.value
+			} else {
+				sleepFor = time.Duration(remaining) * time.Millisecond
+			}
+		}
+		q.mu.Unlock()
+
+		if !hasItem {
+			select {
+			case <-q.wakeupC:
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		timer := time.NewTimer(sleepFor)
+		select {
+		case <-timer.C:
+		case <-q.wakeupC:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package timewheel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func nowMs() int64 {
+	return time.Now().UnixMilli()
+}
+
+func TestDelayQueuePollReturnsEarliestFirst(t *testing.T) {
+	q := NewDelayQueue()
+	q.Offer("b", nowMs()+100)
+	q.Offer("a", nowMs()+10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	first := q.Poll(ctx, nowMs)
+	if first != "a" {
+		t.Errorf("expected \"a\" to be returned first, got %v", first)
+	}
+
+	second := q.Poll(ctx, nowMs)
+	if second != "b" {
+		t.Errorf("expected \"b\" to be returned second, got %v", second)
+	}
+}
+
+func TestDelayQueuePollWakesEarlyOnNewHead(t *testing.T) {
+	q := NewDelayQueue()
+	q.Offer("late", nowMs()+2000)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan any, 1)
+	go func() {
+		done <- q.Poll(ctx, nowMs)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	q.Offer("early", nowMs()+10)
+
+	select {
+	case v := <-done:
+		if v != "early" {
+			t.Errorf("expected the newly offered earlier item, got %v", v)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Poll did not wake up for the new earlier item")
+	}
+}
+
+func TestDelayQueuePollReturnsNilOnCancel(t *testing.T) {
+	q := NewDelayQueue()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan any, 1)
+	go func() {
+		done <- q.Poll(ctx, nowMs)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case v := <-done:
+		if v != nil {
+			t.Errorf("expected nil after cancellation, got %v", v)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Poll did not return after context cancellation")
+	}
+}
@@ -0,0 +1,109 @@
+package timewheel
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetTimerDeliversKeyAndValue(t *testing.T) {
+	var mu sync.Mutex
+	var gotKey string
+	var gotValue any
+
+	tw := NewTimeWheelWithExecutor(10, 50*time.Millisecond, func(key string, value any) {
+		mu.Lock()
+		gotKey = key
+		gotValue = value
+		mu.Unlock()
+	}, 4)
+	defer tw.Stop()
+
+	tw.SetTimer("task1", 42, 150*time.Millisecond)
+
+	time.Sleep(400 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if gotKey != "task1" || gotValue != 42 {
+		t.Errorf("expected exec to be called with (task1, 42), got (%v, %v)", gotKey, gotValue)
+	}
+}
+
+func TestExecutorWheelBoundsConcurrentExecutions(t *testing.T) {
+	const workers = 2
+	var mu sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+
+	tw := NewTimeWheelWithExecutor(10, 20*time.Millisecond, func(key string, value any) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}, workers)
+	defer tw.Stop()
+
+	for i := 0; i < 10; i++ {
+		tw.SetTimer("task-"+strconv.Itoa(i), i, 20*time.Millisecond)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxInFlight > workers {
+		t.Errorf("expected at most %d concurrent executions, observed %d", workers, maxInFlight)
+	}
+}
+
+func TestExecutorWheelAdaptsFuncBasedAPI(t *testing.T) {
+	executed := false
+	tw := NewTimeWheelWithExecutor(10, 20*time.Millisecond, func(key string, value any) {
+		t.Errorf("exec should not be called for a func()-based task, got (%v, %v)", key, value)
+	}, 2)
+	defer tw.Stop()
+
+	tw.AddTask("task1", func() {
+		executed = true
+	}, time.Now().Add(100*time.Millisecond))
+
+	time.Sleep(300 * time.Millisecond)
+
+	if !executed {
+		t.Error("expected the func()-based task to run via the worker pool adapter")
+	}
+}
+
+func TestStopDrainsPendingWorkerTasks(t *testing.T) {
+	var mu sync.Mutex
+	delivered := 0
+
+	tw := NewTimeWheelWithExecutor(10, 20*time.Millisecond, func(key string, value any) {
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	}, 1)
+
+	for i := 0; i < 5; i++ {
+		tw.SetTimer("task-"+strconv.Itoa(i), i, 20*time.Millisecond)
+	}
+
+	// Give every task time to fire into the worker pool before shutdown.
+	time.Sleep(150 * time.Millisecond)
+	tw.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if delivered != 5 {
+		t.Errorf("expected Stop to drain all 5 already-fired tasks, got %d", delivered)
+	}
+}
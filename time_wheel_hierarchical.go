@@ -0,0 +1,262 @@
+package timewheel
+
+import (
+	"container/list"
+	"log"
+	"sync"
+	"time"
+)
+
+// hTaskElement is the payload stored in a hierarchical wheel slot.
+type hTaskElement struct {
+	key       string
+	task      func()
+	executeAt time.Time
+}
+
+// htTaskRecord lets RemoveTask find and delete a task in O(1) no matter
+// which level currently holds it: list/elem always point at where the
+// task last landed, and are kept up to date across cascades.
+type htTaskRecord struct {
+	level *htLevel
+	list  *list.List
+	elem  *list.Element
+}
+
+// htLevel is a single ring in the wheel-of-wheels. Level 0 is the base
+// wheel (the one whose ticks actually fire tasks); every level above it
+// has an interval of its child's interval * wheelSize and only drains into
+// its child when the child completes a full revolution.
+type htLevel struct {
+	interval    time.Duration
+	slotNum     int
+	slots       []*list.List
+	currentSlot int
+	parent      *htLevel
+}
+
+func newHTLevel(interval time.Duration, slotNum int) *htLevel {
+	l := &htLevel{
+		interval: interval,
+		slotNum:  slotNum,
+		slots:    make([]*list.List, slotNum),
+	}
+	for i := 0; i < slotNum; i++ {
+		l.slots[i] = list.New()
+	}
+	return l
+}
+
+// HierarchicalTimeWheel is a multi-level timing wheel (Varghese/Kafka
+// style): a long delay that would otherwise sit in a single slot being
+// decremented on every tick instead overflows into a parent wheel whose
+// tick is wheelSize times coarser, recursively, so each level only ever
+// holds tasks that fit within its own span.
+type HierarchicalTimeWheel struct {
+	sync.Once
+	sync.Mutex
+	baseTick  time.Duration
+	wheelSize int
+	base      *htLevel
+	taskMap   map[string]*htTaskRecord
+
+	ticker       *time.Ticker
+	stopc        chan struct{}
+	addTaskCh    chan *hTaskElement
+	removeTaskCh chan string
+}
+
+// NewHierarchicalTimeWheel creates a hierarchical wheel whose base level
+// ticks every tick and has wheelSize slots. Parent levels (tick*wheelSize,
+// tick*wheelSize^2, ...) are constructed lazily the first time a task's
+// delay doesn't fit in the levels built so far.
+func NewHierarchicalTimeWheel(tick time.Duration, wheelSize int) *HierarchicalTimeWheel {
+	if wheelSize <= 0 {
+		wheelSize = 10
+	}
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	h := &HierarchicalTimeWheel{
+		baseTick:     tick,
+		wheelSize:    wheelSize,
+		base:         newHTLevel(tick, wheelSize),
+		taskMap:      make(map[string]*htTaskRecord),
+		ticker:       time.NewTicker(tick),
+		stopc:        make(chan struct{}),
+		addTaskCh:    make(chan *hTaskElement),
+		removeTaskCh: make(chan string),
+	}
+
+	go h.run()
+	return h
+}
+
+func (h *HierarchicalTimeWheel) Stop() {
+	h.Do(func() {
+		h.ticker.Stop()
+		close(h.stopc)
+	})
+}
+
+func (h *HierarchicalTimeWheel) AddTask(key string, task func(), executeAt time.Time) {
+	h.addTaskCh <- &hTaskElement{key: key, task: task, executeAt: executeAt}
+}
+
+func (h *HierarchicalTimeWheel) RemoveTask(key string) {
+	h.removeTaskCh <- key
+}
+
+func (h *HierarchicalTimeWheel) run() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("hierarchical timewheel panic: %v", err)
+		}
+	}()
+
+	for {
+		select {
+		case <-h.stopc:
+			return
+		case <-h.ticker.C:
+			h.tick()
+		case te := <-h.addTaskCh:
+			h.addTask(te)
+		case key := <-h.removeTaskCh:
+			h.removeTask(key)
+		}
+	}
+}
+
+func (h *HierarchicalTimeWheel) addTask(te *hTaskElement) {
+	h.Lock()
+	defer h.Unlock()
+
+	if _, ok := h.taskMap[te.key]; ok {
+		h.removeTaskLocked(te.key)
+	}
+	h.insert(te.key, te.task, te.executeAt)
+}
+
+func (h *HierarchicalTimeWheel) removeTask(key string) {
+	h.Lock()
+	defer h.Unlock()
+	h.removeTaskLocked(key)
+}
+
+func (h *HierarchicalTimeWheel) removeTaskLocked(key string) {
+	rec, ok := h.taskMap[key]
+	if !ok {
+		return
+	}
+	rec.list.Remove(rec.elem)
+	delete(h.taskMap, key)
+}
+
+// insert walks up from the base level, lazily constructing parent levels,
+// until it finds (or creates) one whose span covers the remaining delay,
+// then pushes the task into that level's slot and records it in taskMap.
+func (h *HierarchicalTimeWheel) insert(key string, task func(), executeAt time.Time) {
+	delay := time.Until(executeAt)
+	if delay < 0 {
+		delay = 0
+	}
+
+	level := h.base
+	for {
+		if pos, ok := levelPos(level, delay, level == h.base); ok {
+			e := level.slots[pos].PushBack(&hTaskElement{key: key, task: task, executeAt: executeAt})
+			h.taskMap[key] = &htTaskRecord{level: level, list: level.slots[pos], elem: e}
+			return
+		}
+		if level.parent == nil {
+			level.parent = newHTLevel(level.interval*time.Duration(h.wheelSize), h.wheelSize)
+		}
+		level = level.parent
+	}
+}
+
+// levelPos reports the slot a delay lands in within level, and whether
+// the delay actually fits in the level's span (interval * slotNum).
+//
+// A non-base level never fires a task directly: it only cascades a slot's
+// contents one level down, and a task reaching that slot gets re-inserted
+// with its (by then much smaller) remaining delay. So a non-base slot must
+// be reached the first time this level's hand gets there, not a full extra
+// revolution later — i.e. as soon as the remaining delay drops below the
+// child level's span (this level's interval), which is one step earlier
+// than the base level's own "round up to the owning tick" slot.
+func levelPos(level *htLevel, delay time.Duration, isBase bool) (pos int, fits bool) {
+	capacity := level.interval * time.Duration(level.slotNum)
+	if delay >= capacity {
+		return 0, false
+	}
+	steps := int(delay / level.interval)
+	if !isBase && steps > 0 {
+		steps--
+	}
+	pos = (level.currentSlot + steps) % level.slotNum
+	return pos, true
+}
+
+func (h *HierarchicalTimeWheel) tick() {
+	h.Lock()
+	defer h.Unlock()
+	h.advance(h.base)
+}
+
+// advance steps the level forward, then fires (base level) or cascades
+// (parent levels) the slot the level was pointing at. Stepping first means
+// a task a cascade puts back into this same level lands in the next slot,
+// not in the one just drained. When a level completes a full revolution
+// its parent is advanced too, which is what drains a parent slot's tasks
+// back down into the right child slot.
+func (h *HierarchicalTimeWheel) advance(level *htLevel) {
+	slot := level.slots[level.currentSlot]
+	level.currentSlot = (level.currentSlot + 1) % level.slotNum
+	if level == h.base {
+		h.execute(slot)
+	} else {
+		h.cascade(slot)
+	}
+
+	if level.currentSlot == 0 && level.parent != nil {
+		h.advance(level.parent)
+	}
+}
+
+func (h *HierarchicalTimeWheel) execute(slot *list.List) {
+	due := make([]*hTaskElement, 0, slot.Len())
+	for e := slot.Front(); e != nil; e = e.Next() {
+		due = append(due, e.Value.(*hTaskElement))
+	}
+	slot.Init()
+
+	for _, te := range due {
+		delete(h.taskMap, te.key)
+		go func(task func()) {
+			defer func() {
+				if err := recover(); err != nil {
+					log.Printf("hierarchical timewheel task panic: %v", err)
+				}
+			}()
+			task()
+		}(te.task)
+	}
+}
+
+// cascade re-inserts every task in a parent slot into the wheel so each
+// lands in the level (and slot) matching its now-shorter remaining delay.
+func (h *HierarchicalTimeWheel) cascade(slot *list.List) {
+	demoted := make([]*hTaskElement, 0, slot.Len())
+	for e := slot.Front(); e != nil; e = e.Next() {
+		demoted = append(demoted, e.Value.(*hTaskElement))
+	}
+	slot.Init()
+
+	for _, te := range demoted {
+		delete(h.taskMap, te.key)
+		h.insert(te.key, te.task, te.executeAt)
+	}
+}
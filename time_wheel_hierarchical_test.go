@@ -0,0 +1,130 @@
+package timewheel
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewHierarchicalTimeWheel(t *testing.T) {
+	h := NewHierarchicalTimeWheel(50*time.Millisecond, 4)
+	defer h.Stop()
+
+	if h == nil {
+		t.Fatal("NewHierarchicalTimeWheel should not return nil")
+	}
+	if h.baseTick != 50*time.Millisecond {
+		t.Errorf("Expected tick to be 50ms, got %v", h.baseTick)
+	}
+	if h.base.parent != nil {
+		t.Error("base level should not have a parent until a task overflows into one")
+	}
+}
+
+func TestHierarchicalAddTaskWithinBaseLevel(t *testing.T) {
+	h := NewHierarchicalTimeWheel(50*time.Millisecond, 4)
+	defer h.Stop()
+
+	done := make(chan struct{})
+	h.AddTask("task1", func() { close(done) }, time.Now().Add(100*time.Millisecond))
+
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Error("task was not executed in time")
+	}
+}
+
+func TestHierarchicalAddTaskOverflowsToParentLevel(t *testing.T) {
+	// base span is only 4*20ms = 80ms, so a 300ms delay must overflow
+	// into a lazily created parent level.
+	h := NewHierarchicalTimeWheel(20*time.Millisecond, 4)
+	defer h.Stop()
+
+	done := make(chan struct{})
+	h.AddTask("task1", func() { close(done) }, time.Now().Add(300*time.Millisecond))
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("task that overflowed into a parent level was never executed")
+	}
+
+	h.Lock()
+	if h.base.parent == nil {
+		h.Unlock()
+		t.Error("expected a parent level to have been created")
+		return
+	}
+	h.Unlock()
+}
+
+func TestHierarchicalOverflowFiresCloseToDelay(t *testing.T) {
+	// base span is 5*20ms = 100ms, so a delay just above that must
+	// overflow into the parent level and be cascaded back down. It
+	// should fire close to its requested delay, not a full parent
+	// tick (another 100ms) late.
+	h := NewHierarchicalTimeWheel(20*time.Millisecond, 5)
+	defer h.Stop()
+
+	const delay = 110 * time.Millisecond
+	start := time.Now()
+	fired := make(chan time.Duration, 1)
+	h.AddTask("task1", func() { fired <- time.Since(start) }, start.Add(delay))
+
+	select {
+	case elapsed := <-fired:
+		if elapsed > delay+60*time.Millisecond {
+			t.Errorf("task fired %v after start, expected close to %v (not a full parent tick late)", elapsed, delay)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("task that overflowed into a parent level was never executed")
+	}
+}
+
+func TestHierarchicalRemoveTask(t *testing.T) {
+	h := NewHierarchicalTimeWheel(20*time.Millisecond, 4)
+	defer h.Stop()
+
+	executed := false
+	h.AddTask("task1", func() { executed = true }, time.Now().Add(300*time.Millisecond))
+	h.RemoveTask("task1")
+
+	time.Sleep(600 * time.Millisecond)
+	if executed {
+		t.Error("task should have been removed before it fired")
+	}
+}
+
+func TestHierarchicalConcurrentOperations(t *testing.T) {
+	h := NewHierarchicalTimeWheel(20*time.Millisecond, 4)
+	defer h.Stop()
+
+	const taskCount = 50
+	var mu sync.Mutex
+	executed := make(map[int]bool)
+
+	for i := 0; i < taskCount; i++ {
+		i := i
+		h.AddTask(
+			"task-"+strconv.Itoa(i),
+			func() {
+				mu.Lock()
+				executed[i] = true
+				mu.Unlock()
+			},
+			time.Now().Add(200*time.Millisecond),
+		)
+	}
+
+	time.Sleep(600 * time.Millisecond)
+
+	mu.Lock()
+	count := len(executed)
+	mu.Unlock()
+
+	if count != taskCount {
+		t.Errorf("expected %d tasks to execute, got %d", taskCount, count)
+	}
+}
@@ -0,0 +1,57 @@
+package timewheel
+
+import (
+	"container/list"
+	"time"
+)
+
+// Scheduler decides when a periodic task scheduled via ScheduleFunc should
+// run next. Next is given the previous run time and returns the next one;
+// returning the zero Time stops the schedule.
+type Scheduler interface {
+	Next(prev time.Time) time.Time
+}
+
+// ScheduleFunc runs f according to s, starting at the time s.Next(time.Now())
+// returns, and reschedules it every time it fires by asking s for the run
+// after that. It returns a Timer so the schedule can be canceled with Stop
+// or redirected with Reset, the same as a timer created with AfterFunc.
+func (t *TimeWheel) ScheduleFunc(s Scheduler, f func()) *Timer {
+	timer := &Timer{tw: t}
+
+	first := s.Next(time.Now())
+	if first.IsZero() {
+		return timer
+	}
+
+	pos, cycle := t.getPosAndCycle(first)
+	done := make(chan *list.Element, 1)
+	t.addTaskCh <- &taskElement{
+		task:      f,
+		pos:       pos,
+		cycle:     cycle,
+		doneCh:    done,
+		scheduler: s,
+		owner:     timer,
+	}
+	timer.setElem(<-done)
+	return timer
+}
+
+// intervalScheduler is a Scheduler that repeats at a fixed period.
+type intervalScheduler struct {
+	interval time.Duration
+}
+
+// NewIntervalScheduler returns a Scheduler that fires every interval,
+// suitable for heartbeat timers and periodic sweeps.
+func NewIntervalScheduler(interval time.Duration) Scheduler {
+	return intervalScheduler{interval: interval}
+}
+
+func (s intervalScheduler) Next(prev time.Time) time.Time {
+	if s.interval <= 0 {
+		return time.Time{}
+	}
+	return prev.Add(s.interval)
+}
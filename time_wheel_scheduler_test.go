@@ -0,0 +1,134 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduleFuncRunsRepeatedly(t *testing.T) {
+	tw := NewTimeWheel(10, 50*time.Millisecond)
+
+	var mu sync.Mutex
+	count := 0
+	timer := tw.ScheduleFunc(NewIntervalScheduler(100*time.Millisecond), func() {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	defer timer.Stop()
+
+	time.Sleep(550 * time.Millisecond)
+
+	mu.Lock()
+	got := count
+	mu.Unlock()
+
+	if got < 3 {
+		t.Errorf("expected ScheduleFunc to have fired at least 3 times, got %d", got)
+	}
+}
+
+func TestScheduleFuncStopEndsRepetition(t *testing.T) {
+	tw := NewTimeWheel(10, 50*time.Millisecond)
+
+	var mu sync.Mutex
+	count := 0
+	timer := tw.ScheduleFunc(NewIntervalScheduler(100*time.Millisecond), func() {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+
+	time.Sleep(150 * time.Millisecond)
+	timer.Stop()
+
+	mu.Lock()
+	afterStop := count
+	mu.Unlock()
+
+	time.Sleep(300 * time.Millisecond)
+
+	mu.Lock()
+	final := count
+	mu.Unlock()
+
+	if final != afterStop {
+		t.Errorf("expected no further executions after Stop, went from %d to %d", afterStop, final)
+	}
+}
+
+func TestScheduleFuncWithNeverFiringSchedulerDoesNotPanic(t *testing.T) {
+	tw := NewTimeWheel(10, 10*time.Millisecond)
+	defer tw.Stop()
+
+	// NewIntervalScheduler(0) has a Next that returns the zero Time right
+	// away, so ScheduleFunc never actually schedules anything and returns
+	// a Timer with a nil elem.
+	timer := tw.ScheduleFunc(NewIntervalScheduler(0), func() {})
+	if timer.Stop() {
+		t.Error("Stop should report false for a schedule that never actually ran")
+	}
+	if timer.Reset(50 * time.Millisecond) {
+		t.Error("Reset should report false for a schedule that never actually ran")
+	}
+
+	// The wheel must still be alive: mishandling the nil elem used to
+	// panic run() and take every other scheduled task down with it.
+	done := make(chan struct{})
+	tw.AfterFunc(20*time.Millisecond, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timewheel stopped processing tasks after Stop/Reset on a nil-elem timer")
+	}
+}
+
+func TestScheduleFuncStopRacingReschedule(t *testing.T) {
+	// A short interval forces many fire-then-reschedule cycles (each one
+	// moving the task to a new *list.Element) while Stop is hammered
+	// concurrently, exercising the window where Stop's elem snapshot can
+	// go stale mid-reschedule.
+	tw := NewTimeWheel(10, time.Millisecond)
+
+	var mu sync.Mutex
+	count := 0
+	timer := tw.ScheduleFunc(NewIntervalScheduler(time.Millisecond), func() {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; i < 50; i++ {
+			timer.Stop()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	<-stopped
+
+	mu.Lock()
+	afterStop := count
+	mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	final := count
+	mu.Unlock()
+
+	if final != afterStop {
+		t.Errorf("expected no further executions once Stop succeeded, went from %d to %d", afterStop, final)
+	}
+}
+
+func TestIntervalSchedulerNext(t *testing.T) {
+	s := NewIntervalScheduler(10 * time.Second)
+	prev := time.Now()
+	next := s.Next(prev)
+	if !next.Equal(prev.Add(10 * time.Second)) {
+		t.Errorf("expected Next to add the interval, got %v", next.Sub(prev))
+	}
+}
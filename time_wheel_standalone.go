@@ -2,34 +2,100 @@ package timewheel
 
 import (
 	"container/list"
+	"context"
 	"sync"
 	"time"
 	"log"
 )
 
 type taskElement struct {
-	task  func() 
-	pos   int 
-	cycle int 
-	key   string 
+	task      func()
+	value     any
+	pos       int
+	cycle     int
+	key       string
+	doneCh    chan *list.Element
+	scheduler Scheduler
+	owner     *Timer
+	// dead marks a task canceled via Timer.Stop/Reset, or one that has fired
+	// with no further run scheduled. It's the source of
+	// truth for cancellation instead of the Timer's elem snapshot, since a
+	// periodic task's elem goes stale the instant it fires and reschedules
+	// into a new one; dead is only ever read and written from run()'s
+	// goroutine, so it needs no separate lock.
+	dead bool
+}
+
+// timingTask is what actually crosses the worker pool's channel: either
+// fn (a task added through the func()-based API) or, for one added via
+// SetTimer, the key/value pair to hand to the wheel's exec callback.
+type timingTask struct {
+	key   string
+	value any
+	fn    func()
 }
 
 
 type TimeWheel struct {
 	sync.Once
 	interval 		time.Duration
-	ticker 			*time.Ticker 
+	startTime		time.Time
+	dq       		*DelayQueue
+	tickCh   		chan int
+	ctx      		context.Context
+	cancel   		context.CancelFunc
 	stopc	    	chan struct{}
 	addTaskCh   	chan *taskElement
 	removeTaskCh 	chan string
+	stopTimerCh 	chan *stopTimerRequest
+	moveTaskCh 		chan *moveTaskRequest
 	slots 			[]*list.List
+	slotExpiry 		[]int64
 	slotNum 		int
-	currentSlot 	int
-	taskMap 		map[string]*taskElement
+	taskMap 		map[string]*list.Element
+	doneCh   		chan struct{}
+	exec     		func(key string, value any)
+	workerCh 		chan timingTask
+	workerWg 		sync.WaitGroup
 }
 
+// Timer is a handle returned by AfterFunc or ScheduleFunc that allows the
+// scheduled call to be canceled or rescheduled without going through the
+// keyed API. elem is guarded by mu because a periodic task scheduled via
+// ScheduleFunc moves to a new *list.Element every time it fires.
+type Timer struct {
+	tw   *TimeWheel
+	mu   sync.Mutex
+	elem *list.Element
+}
 
-func NewTimeWheel(slotNum int, interval time.Duration) *TimeWheel {
+func (tm *Timer) getElem() *list.Element {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.elem
+}
+
+func (tm *Timer) setElem(elem *list.Element) {
+	tm.mu.Lock()
+	tm.elem = elem
+	tm.mu.Unlock()
+}
+
+type stopTimerRequest struct {
+	elem   *list.Element
+	result chan bool
+}
+
+// moveTaskRequest asks run() to relocate a keyed task to a new delay
+// in place, reusing its existing taskElement rather than allocating one.
+type moveTaskRequest struct {
+	key      string
+	newDelay time.Duration
+	result   chan bool
+}
+
+
+func newTimeWheel(slotNum int, interval time.Duration) *TimeWheel {
 	if slotNum <= 0 {
 		slotNum = 10
 	}
@@ -38,31 +104,138 @@ func NewTimeWheel(slotNum int, interval time.Duration) *TimeWheel {
 		interval = time.Second
 	}
 
-	t := TimeWheel {
-		interval: 	interval, 
-		ticker: 	time.NewTicker(interval), 
-		stopc: 		make(chan struct{}), 
-		addTaskCh: 	make(chan *taskElement), 
-		removeTaskCh: make(chan string), 
-		slots: 		make([]*list.List, slotNum), 
-		slotNum: 	slotNum, 
-		currentSlot: 0, 
-		taskMap: make(map[string]*taskElement), 
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &TimeWheel{
+		interval: 	interval,
+		startTime: 	time.Now(),
+		dq: 		NewDelayQueue(),
+		tickCh: 	make(chan int),
+		ctx: 		ctx,
+		cancel: 	cancel,
+		stopc: 		make(chan struct{}),
+		addTaskCh: 	make(chan *taskElement),
+		removeTaskCh: make(chan string),
+		stopTimerCh: make(chan *stopTimerRequest),
+		moveTaskCh: make(chan *moveTaskRequest),
+		slots: 		make([]*list.List, slotNum),
+		slotExpiry: make([]int64, slotNum),
+		slotNum: 	slotNum,
+		taskMap: make(map[string]*list.Element),
+		doneCh: make(chan struct{}),
 	}
 
 	for i := 0; i < slotNum; i++ {
 		t.slots[i] = list.New()
 	}
 
+	return t
+}
+
+func NewTimeWheel(slotNum int, interval time.Duration) *TimeWheel {
+	t := newTimeWheel(slotNum, interval)
+	go t.run()
+	go t.poll()
+	return t
+}
+
+// NewTimeWheelWithExecutor creates a TimeWheel that, instead of spawning a
+// goroutine per fired task, hands each due (key, value) pair added via
+// SetTimer to a fixed pool of workers consumer goroutines — modeled on
+// go-zero's Execute, this bounds the goroutine fan-out a burst of expiring
+// tasks can cause. Tasks added through the plain func()-based API (AddTask,
+// AfterFunc, ScheduleFunc) still go through the same pool via a thin
+// adapter, so they share its backpressure instead of spawning freely.
+func NewTimeWheelWithExecutor(slotNum int, interval time.Duration, exec func(key string, value any), workers int) *TimeWheel {
+	if workers <= 0 {
+		workers = 8
+	}
+
+	t := newTimeWheel(slotNum, interval)
+	t.exec = exec
+	t.workerCh = make(chan timingTask, workers)
+
+	for i := 0; i < workers; i++ {
+		t.workerWg.Add(1)
+		go t.worker()
+	}
+
 	go t.run()
-	return &t
+	go t.poll()
+	return t
 }
 
+// SetTimer schedules value to be delivered to the executor wheel's exec
+// callback as exec(key, value) after delay. It is the Cache/key-value
+// sibling of AddTask, meant for wheels built with NewTimeWheelWithExecutor.
+func (t *TimeWheel) SetTimer(key string, value any, delay time.Duration) {
+	pos, cycle := t.getPosAndCycle(time.Now().Add(delay))
+	t.addTaskCh <- &taskElement{
+		pos:   pos,
+		cycle: cycle,
+		key:   key,
+		value: value,
+	}
+}
 
+// worker drains workerCh, running each timingTask as it arrives. On
+// shutdown, run() closes workerCh once it has stopped producing new tasks,
+// so ranging over it here drains whatever was already in flight before the
+// goroutine exits.
+func (t *TimeWheel) worker() {
+	defer t.workerWg.Done()
+	for task := range t.workerCh {
+		t.runTimingTask(task)
+	}
+}
+
+func (t *TimeWheel) runTimingTask(task timingTask) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("timewheel task panic: %v", err)
+		}
+	}()
+
+	if task.fn != nil {
+		task.fn()
+		return
+	}
+	if t.exec != nil {
+		t.exec(task.key, task.value)
+	}
+}
+
+// poll feeds run() a slot index whenever the DelayQueue's earliest bucket
+// comes due, letting the wheel sleep until the next real deadline instead
+// of waking up once per interval regardless of whether any slot is due.
+func (t *TimeWheel) poll() {
+	for {
+		v := t.dq.Poll(t.ctx, nowMillis)
+		if v == nil {
+			return
+		}
+		select {
+		case t.tickCh <- v.(int):
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+func nowMillis() int64 {
+	return time.Now().UnixMilli()
+}
+
+// Stop shuts the wheel down. If it was built with NewTimeWheelWithExecutor,
+// Stop blocks until run() has exited and every task already handed to the
+// worker pool has been drained, so a fired-but-not-yet-executed callback is
+// still delivered rather than dropped.
 func (t *TimeWheel) Stop() {
 	t.Do(func() {
-		t.ticker.Stop()
+		t.cancel()
 		close(t.stopc)
+		<-t.doneCh
+		t.workerWg.Wait()
 	})
 }
 
@@ -70,10 +243,10 @@ func (t *TimeWheel) Stop() {
 func (t *TimeWheel) AddTask(key string, task func(), executeAt time.Time) {
 	pos, cycle := t.getPosAndCycle(executeAt)
 	t.addTaskCh <- &taskElement{
-		task: task, 
-		pos: pos, 
-		cycle: cycle, 
-		key: key, 
+		task: task,
+		pos: pos,
+		cycle: cycle,
+		key: key,
 	}
 }
 
@@ -81,97 +254,340 @@ func (t *TimeWheel) RemoveTask(key string) {
 	t.removeTaskCh <- key
 }
 
+// MoveTask reschedules the keyed task to fire after newDelay, relocating
+// its existing taskElement directly to the new slot in O(1) instead of
+// removing and re-adding it. It reports whether key had a pending task.
+func (t *TimeWheel) MoveTask(key string, newDelay time.Duration) bool {
+	result := make(chan bool, 1)
+	t.moveTaskCh <- &moveTaskRequest{key: key, newDelay: newDelay, result: result}
+	return <-result
+}
+
+// AfterFunc schedules f to run after d and returns a Timer that can be
+// stopped or reset in O(1), without the caller having to make up a key
+// or this wheel scanning a slot's list to cancel it.
+func (t *TimeWheel) AfterFunc(d time.Duration, f func()) *Timer {
+	pos, cycle := t.getPosAndCycle(time.Now().Add(d))
+	done := make(chan *list.Element, 1)
+	t.addTaskCh <- &taskElement{
+		task:   f,
+		pos:    pos,
+		cycle:  cycle,
+		doneCh: done,
+	}
+	return &Timer{tw: t, elem: <-done}
+}
+
+// Stop cancels the timer, returning false if the call had already fired
+// or had already been stopped. It also reports false for a Timer whose
+// schedule never actually had a first run (e.g. ScheduleFunc with a
+// Scheduler whose first Next is zero), which carries a nil elem.
+func (tm *Timer) Stop() bool {
+	elem := tm.getElem()
+	if elem == nil {
+		return false
+	}
+	result := make(chan bool, 1)
+	tm.tw.stopTimerCh <- &stopTimerRequest{elem: elem, result: result}
+	return <-result
+}
+
+// Reset stops the timer and reschedules f to run after d, returning
+// whether the timer was still active before the reset. It reports false
+// without scheduling anything for a Timer with a nil elem (see Stop).
+func (tm *Timer) Reset(d time.Duration) bool {
+	elem := tm.getElem()
+	if elem == nil {
+		return false
+	}
+	result := make(chan bool, 1)
+	tm.tw.stopTimerCh <- &stopTimerRequest{elem: elem, result: result}
+	wasActive := <-result
+
+	te := elem.Value.(*taskElement)
+	pos, cycle := tm.tw.getPosAndCycle(time.Now().Add(d))
+	done := make(chan *list.Element, 1)
+	tm.tw.addTaskCh <- &taskElement{
+		task:      te.task,
+		pos:       pos,
+		cycle:     cycle,
+		key:       te.key,
+		doneCh:    done,
+		scheduler: te.scheduler,
+		owner:     tm,
+	}
+	tm.setElem(<-done)
+	return wasActive
+}
+
 func (t *TimeWheel) run() {
 	defer func() {
 		if err := recover(); err != nil {
 			log.Printf("timewheel panic: %v", err)
 		}
+		if t.workerCh != nil {
+			close(t.workerCh)
+		}
+		close(t.doneCh)
 	}()
 
 	for {
 		select {
 		case <-t.stopc:
-			return 
-		case <-t.ticker.C:
-			t.tick()
+			return
+		case pos := <-t.tickCh:
+			t.tick(pos)
 		case task := <-t.addTaskCh:
 			t.addTask(task)
 		case removeKey := <-t.removeTaskCh:
 			t.removeTask(removeKey)
+		case req := <-t.stopTimerCh:
+			req.result <- t.cancelElement(req.elem)
+		case req := <-t.moveTaskCh:
+			req.result <- t.moveTask(req.key, req.newDelay)
 		}
 	}
 
 
 }
 
-func (t *TimeWheel) tick() {
-	list := t.slots[t.currentSlot]
-	defer t.circularIncr()
-	
-	t.execute(list)
+// tick fires due tasks in slot pos, which the DelayQueue has just reported
+// as expired. If the slot still holds tasks waiting out further laps
+// (cycle > 0), its bucket is re-offered for its next occurrence instead of
+// being re-armed on a fixed schedule.
+func (t *TimeWheel) tick(pos int) {
+	t.execute(t.slots[pos])
+	if t.slots[pos].Len() > 0 {
+		t.slotExpiry[pos] += int64(t.interval/time.Millisecond) * int64(t.slotNum)
+		t.dq.Offer(pos, t.slotExpiry[pos])
+	}
+}
+
+// scheduleSlot arms slot pos's next occurrence in the DelayQueue. It is
+// only called when a task lands in a slot that was empty, since a slot
+// already holding tasks is already scheduled.
+func (t *TimeWheel) scheduleSlot(pos int) {
+	exp := t.nextOccurrenceMs(pos)
+	t.slotExpiry[pos] = exp
+	t.dq.Offer(pos, exp)
 }
 
-func (t *TimeWheel) circularIncr() {
-	t.currentSlot = (t.currentSlot + 1) % t.slotNum
+// nextOccurrenceMs returns, in unix milliseconds, the next time slot pos
+// comes due given how many ticks have elapsed since the wheel started.
+func (t *TimeWheel) nextOccurrenceMs(pos int) int64 {
+	nowTick := int64(time.Since(t.startTime) / t.interval)
+	nowPos := int(nowTick % int64(t.slotNum))
+	stepsAhead := int64((pos - nowPos + t.slotNum) % t.slotNum)
+	targetTick := nowTick + stepsAhead
+	return t.startTime.UnixMilli() + targetTick*int64(t.interval/time.Millisecond)
 }
 
 func (t *TimeWheel) execute(list *list.List) {
 	for e := list.Front(); e != nil; {
 		task, _ := e.Value.(*taskElement)
+		if task.dead {
+			next := e.Next()
+			list.Remove(e)
+			if task.key != "" {
+				delete(t.taskMap, task.key)
+			}
+			e = next
+			continue
+		}
 		if task.cycle > 0 {
 			task.cycle--
 			e = e.Next()
 			continue
 		}
 
-		go func(task *taskElement) {
-			defer func() {
-				if err := recover(); err != nil {
-					log.Printf("timewheel task panic: %v", err)
-				}
-			}()
-			task.task()
-		}(task)
+		t.dispatch(task)
 
 		next := e.Next()
 		list.Remove(e)
 		e = next
+		t.rescheduleOrForget(task)
+	}
+}
+
+// dispatch hands a fired task off for execution. Wheels built with
+// NewTimeWheelWithExecutor route it through the bounded worker pool;
+// plain wheels keep the original one-goroutine-per-task behavior.
+func (t *TimeWheel) dispatch(task *taskElement) {
+	if t.workerCh != nil {
+		t.workerCh <- timingTask{key: task.key, value: task.value, fn: task.task}
+		return
+	}
+
+	go func(task *taskElement) {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Printf("timewheel task panic: %v", err)
+			}
+		}()
+		task.task()
+	}(task)
+}
+
+// rescheduleOrForget is called once a task has fired. If it carries a
+// Scheduler, it asks the Scheduler for the next run time and reinserts
+// the same taskElement into the slot that time lands in, keeping
+// taskMap and the owning Timer (if any) pointed at the new slot.
+// Otherwise the task is marked dead, so a later Timer.Stop reports false,
+// and dropped from taskMap.
+func (t *TimeWheel) rescheduleOrForget(task *taskElement) {
+	if task.scheduler != nil {
+		if next := task.scheduler.Next(time.Now()); !next.IsZero() {
+			pos, cycle := t.getPosAndCycle(next)
+			task.pos = pos
+			task.cycle = cycle
+			wasEmpty := t.slots[pos].Len() == 0
+			elem := t.slots[pos].PushBack(task)
+			if task.key != "" {
+				t.taskMap[task.key] = elem
+			}
+			if task.owner != nil {
+				task.owner.setElem(elem)
+			}
+			if wasEmpty {
+				t.scheduleSlot(pos)
+			}
+			return
+		}
+	}
+
+	task.dead = true
+	if task.key != "" {
 		delete(t.taskMap, task.key)
 	}
 }
 
 
 func (t *TimeWheel) getPosAndCycle(executeAt time.Time) (pos int, cycle int) {
-	delay := int(time.Until(executeAt).Milliseconds())
+	delay := time.Until(executeAt)
 	// 确保delay不为负
 	if delay < 0 {
 		delay = 0
 	}
-	
-	intervalMs := int(t.interval.Milliseconds())
-	cycle = delay / (t.slotNum * intervalMs)
-	pos = (t.currentSlot + (delay / intervalMs) % t.slotNum) % t.slotNum
+
+	// Round the number of ticks up rather than truncating, so a task never
+	// fires before executeAt just because it landed a few microseconds
+	// short of a whole tick.
+	steps := int64((delay + t.interval - 1) / t.interval)
+	nowTick := int64(time.Since(t.startTime) / t.interval)
+	targetTick := nowTick + steps
+	cycle = int(steps / int64(t.slotNum))
+	pos = int(targetTick % int64(t.slotNum))
 	return
 }
 
 func (t *TimeWheel) addTask(task *taskElement) {
-	list := t.slots[task.pos]
-	if _, ok := t.taskMap[task.key]; ok {
-		t.removeTask(task.key)
+	if task.key != "" {
+		if elem, ok := t.taskMap[task.key]; ok {
+			t.removeElement(elem)
+		}
 	}
 
-	t.taskMap[task.key] = task
-	list.PushBack(task)
+	slot := t.slots[task.pos]
+	wasEmpty := slot.Len() == 0
+	elem := slot.PushBack(task)
+	if task.key != "" {
+		t.taskMap[task.key] = elem
+	}
+	if task.doneCh != nil {
+		task.doneCh <- elem
+	}
+	if wasEmpty {
+		t.scheduleSlot(task.pos)
+	}
 }
 
 func (t *TimeWheel) removeTask(key string) {
-	if task, ok := t.taskMap[key]; ok {
-		delete(t.taskMap, key)
-		for e := t.slots[task.pos].Front(); e != nil; e = e.Next() {
-			if taskEle, ok := e.Value.(*taskElement); ok && taskEle.key == key {
-				t.slots[task.pos].Remove(e)
-				break
-			}
-		}
+	if elem, ok := t.taskMap[key]; ok {
+		t.removeElement(elem)
+	}
+}
+
+// removeElement cancels the task backing elem in O(1): no scan of the
+// slot's list is needed since elem already points at its node in it.
+// It reports whether the task was still pending (false if it had
+// already fired or been removed). A rescheduled task (one with a
+// Scheduler) reuses the same taskElement across every run, so task.pos
+// always names its *current* slot; comparing the slot's length before
+// and after the removal (rather than trusting a shared "active" flag)
+// is what lets this tell a stale elem from an earlier run apart from
+// the live one now sitting in a different slot.
+func (t *TimeWheel) removeElement(elem *list.Element) bool {
+	if elem == nil {
+		return false
+	}
+	task, _ := elem.Value.(*taskElement)
+	if task == nil {
+		return false
+	}
+
+	slot := t.slots[task.pos]
+	before := slot.Len()
+	slot.Remove(elem)
+	if slot.Len() == before {
+		return false
+	}
+
+	if task.key != "" {
+		delete(t.taskMap, task.key)
+	}
+	return true
+}
+
+// cancelElement cancels the Timer-owned task backing elem, reporting
+// whether it was still pending. Unlike removeElement (used by the keyed
+// API, which always looks its elem up fresh from taskMap inside run()),
+// elem here may be a Timer's stale snapshot: for a periodic task,
+// rescheduleOrForget reuses the same *taskElement across every run but
+// wraps it in a new *list.Element each time it refires, so an elem
+// captured before that has already happened points at a node no longer
+// in any slot's list. elem.Value is still the live taskElement, though,
+// so cancellation is done by flipping its dead flag (checked in execute)
+// rather than by trusting elem to still be the task's current node.
+func (t *TimeWheel) cancelElement(elem *list.Element) bool {
+	if elem == nil {
+		return false
+	}
+	task, _ := elem.Value.(*taskElement)
+	if task == nil || task.dead {
+		return false
+	}
+	task.dead = true
+
+	// Best-effort immediate removal: a no-op if elem is stale (it belongs
+	// to a list it's already been unlinked from), in which case the live
+	// node is cleaned up the next time execute reaches it.
+	t.slots[task.pos].Remove(elem)
+	if task.key != "" {
+		delete(t.taskMap, task.key)
+	}
+	return true
+}
+
+// moveTask relocates the keyed task to the slot matching newDelay,
+// reusing its existing taskElement so no task payload is re-allocated.
+// It reports whether key had a pending task to move.
+func (t *TimeWheel) moveTask(key string, newDelay time.Duration) bool {
+	elem, ok := t.taskMap[key]
+	if !ok {
+		return false
+	}
+
+	task := elem.Value.(*taskElement)
+	t.slots[task.pos].Remove(elem)
+
+	pos, cycle := t.getPosAndCycle(time.Now().Add(newDelay))
+	task.pos = pos
+	task.cycle = cycle
+	wasEmpty := t.slots[pos].Len() == 0
+	newElem := t.slots[pos].PushBack(task)
+	t.taskMap[key] = newElem
+	if wasEmpty {
+		t.scheduleSlot(pos)
 	}
+	return true
 }
\ No newline at end of file
@@ -139,3 +139,168 @@ func TestConcurrentOperations(t *testing.T) {
 		t.Errorf("Expected %d tasks to execute, but %d executed", taskCount, tasksExecuted)
 	}
 }
+
+func TestAfterFunc(t *testing.T) {
+	tw := NewTimeWheel(10, 100*time.Millisecond)
+
+	var mu sync.Mutex
+	taskExecuted := false
+	tw.AfterFunc(200*time.Millisecond, func() {
+		mu.Lock()
+		taskExecuted = true
+		mu.Unlock()
+	})
+
+	time.Sleep(500 * time.Millisecond)
+
+	mu.Lock()
+	executed := taskExecuted
+	mu.Unlock()
+	if !executed {
+		t.Error("Task scheduled via AfterFunc was not executed")
+	}
+}
+
+func TestTimerStop(t *testing.T) {
+	tw := NewTimeWheel(10, 100*time.Millisecond)
+
+	var mu sync.Mutex
+	taskExecuted := false
+	timer := tw.AfterFunc(300*time.Millisecond, func() {
+		mu.Lock()
+		taskExecuted = true
+		mu.Unlock()
+	})
+
+	if !timer.Stop() {
+		t.Error("Stop should report true for a still-pending timer")
+	}
+	if timer.Stop() {
+		t.Error("Stop should report false when called again on an already-stopped timer")
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	mu.Lock()
+	executed := taskExecuted
+	mu.Unlock()
+	if executed {
+		t.Error("Task should not be executed after its timer was stopped")
+	}
+}
+
+func TestTimerReset(t *testing.T) {
+	tw := NewTimeWheel(10, 100*time.Millisecond)
+
+	executionCount := 0
+	var mu sync.Mutex
+	timer := tw.AfterFunc(150*time.Millisecond, func() {
+		mu.Lock()
+		executionCount++
+		mu.Unlock()
+	})
+
+	// 在原定触发时间之前重置，原有调度不应执行
+	if !timer.Reset(300 * time.Millisecond) {
+		t.Error("Reset should report true when the timer was still pending")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	mu.Lock()
+	early := executionCount
+	mu.Unlock()
+	if early != 0 {
+		t.Error("Task should not have executed before the reset delay elapsed")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	mu.Lock()
+	final := executionCount
+	mu.Unlock()
+	if final != 1 {
+		t.Errorf("Expected task to execute once after reset, got %d", final)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	tw := NewTimeWheel(10, 100*time.Millisecond)
+
+	executionCount := 0
+	var mu sync.Mutex
+	tw.AddTask("task1", func() {
+		mu.Lock()
+		executionCount++
+		mu.Unlock()
+	}, time.Now().Add(150*time.Millisecond))
+
+	if !tw.MoveTask("task1", 400*time.Millisecond) {
+		t.Error("expected MoveTask to report the task was pending")
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	mu.Lock()
+	early := executionCount
+	mu.Unlock()
+	if early != 0 {
+		t.Error("task should not have executed before its moved delay elapsed")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	mu.Lock()
+	final := executionCount
+	mu.Unlock()
+	if final != 1 {
+		t.Errorf("expected task to execute once after the moved delay, got %d", final)
+	}
+
+	if tw.MoveTask("no-such-task", time.Second) {
+		t.Error("expected MoveTask to report false for an unknown key")
+	}
+}
+
+// TestAddTaskSkipsEmptySlots exercises a delay spanning several empty
+// slots, which the DelayQueue-driven tick loop must skip over without
+// waking up for each one, and still fire the task on time.
+func TestAddTaskSkipsEmptySlots(t *testing.T) {
+	tw := NewTimeWheel(20, 20*time.Millisecond)
+
+	start := time.Now()
+	fired := make(chan time.Duration, 1)
+	tw.AddTask("task1", func() {
+		fired <- time.Since(start)
+	}, start.Add(300*time.Millisecond))
+
+	select {
+	case d := <-fired:
+		if d < 280*time.Millisecond {
+			t.Errorf("task fired too early, after %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("task did not fire")
+	}
+}
+
+// TestIdleTimeWheelDoesNotTick confirms a wheel with nothing scheduled
+// never wakes its tick loop, which is the whole point of driving ticks
+// off a DelayQueue instead of a fixed time.Ticker.
+func TestIdleTimeWheelDoesNotTick(t *testing.T) {
+	tw := NewTimeWheel(10, 10*time.Millisecond)
+	defer tw.Stop()
+
+	time.Sleep(150 * time.Millisecond)
+
+	// With no bucket offered to the DelayQueue, poll() has nothing to wait
+	// for and so never sends a tick.
+	tw.dq.mu.Lock()
+	queued := len(tw.dq.heap)
+	tw.dq.mu.Unlock()
+	if queued != 0 {
+		t.Errorf("idle wheel should have no bucket in its DelayQueue, got %d", queued)
+	}
+
+	select {
+	case pos := <-tw.tickCh:
+		t.Errorf("idle wheel should not have produced a tick, got pos %d", pos)
+	default:
+	}
+}